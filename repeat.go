@@ -0,0 +1,62 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// Stop is a sentinel error fn can return from Repeat or RepeatWithJitter to
+// terminate the loop deliberately, as distinct from the context being
+// cancelled or fn failing outright.
+var Stop = errors.New("retry: stop repeating")
+
+// Repeat invokes fn roughly once every interval until ctx is cancelled or fn
+// returns a non-nil error. It is a distinct subsystem from Do: Do retries a
+// failing operation, while Repeat paces repeated invocations of an
+// operation that is expected to keep succeeding.
+//
+// Repeat returns ctx.Err() if the context was cancelled, nil if fn returned
+// Stop, or the error from fn otherwise.
+func Repeat(ctx context.Context, interval time.Duration, fn func() error) error {
+	return RepeatWithJitter(ctx, interval, 0, fn)
+}
+
+// RepeatWithJitter is like Repeat but randomizes each tick's sleep by up to
+// jitterFraction of interval in either direction: interval +
+// rand(-jitterFraction*interval, +jitterFraction*interval), clamped to be
+// non-negative. Spreading ticks like this avoids many callers on the same
+// interval waking in lockstep.
+func RepeatWithJitter(ctx context.Context, interval time.Duration, jitterFraction float64, fn func() error) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(jitteredInterval(interval, jitterFraction)):
+		}
+
+		if err := fn(); err != nil {
+			if errors.Is(err, Stop) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// jitteredInterval applies up to +/- jitterFraction of randomness to
+// interval, clamped to be non-negative.
+func jitteredInterval(interval time.Duration, jitterFraction float64) time.Duration {
+	spread := time.Duration(jitterFraction * float64(interval))
+	if spread <= 0 {
+		return interval
+	}
+
+	offset := time.Duration(rand.Int63n(int64(2*spread+1))) - spread
+	d := interval + offset
+	if d < 0 {
+		d = 0
+	}
+	return d
+}
@@ -0,0 +1,85 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRepeat(t *testing.T) {
+	count := 0
+	sentinel := errors.New("terminal failure")
+
+	err := Repeat(context.Background(), 5*time.Millisecond, func() error {
+		count++
+		if count == 3 {
+			return sentinel
+		}
+		return nil
+	})
+
+	if !errors.Is(err, sentinel) {
+		t.Errorf("expected sentinel error, got: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("expected 3 invocations, got: %d", count)
+	}
+}
+
+func TestRepeatStop(t *testing.T) {
+	count := 0
+
+	err := Repeat(context.Background(), 5*time.Millisecond, func() error {
+		count++
+		if count == 2 {
+			return Stop
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Errorf("expected nil error after Stop, got: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 invocations, got: %d", count)
+	}
+}
+
+func TestRepeatContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	count := 0
+	err := Repeat(ctx, 50*time.Millisecond, func() error {
+		count++
+		return nil
+	})
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected DeadlineExceeded, got: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("expected no invocations before the first tick, got: %d", count)
+	}
+}
+
+func TestRepeatWithJitterBounds(t *testing.T) {
+	interval := 20 * time.Millisecond
+	jitterFraction := 0.5
+
+	for i := 0; i < 20; i++ {
+		d := jitteredInterval(interval, jitterFraction)
+		if d < 10*time.Millisecond || d > 30*time.Millisecond {
+			t.Errorf("jitteredInterval() = %v, want within [10ms, 30ms]", d)
+		}
+	}
+}
+
+func TestJitteredIntervalNoJitter(t *testing.T) {
+	interval := 20 * time.Millisecond
+
+	if d := jitteredInterval(interval, 0); d != interval {
+		t.Errorf("expected interval unchanged with zero jitter, got: %v", d)
+	}
+}
@@ -4,13 +4,20 @@ package retry
 
 import (
 	"context"
+	"errors"
+	"log/slog"
 	"math/rand"
+	"net/http"
+	"strconv"
 	"time"
 )
 
 // Config defines the settings for retrying an operation.
 type Config struct {
-	// Attempts specifies the maximum number of attempts (including the initial try).
+	// Attempts specifies the maximum number of attempts (including the
+	// initial try). Backoff.Ongoing treats Attempts <= 0 as unbounded,
+	// retrying until ctx is done; Do instead treats Attempts <= 0 as exactly
+	// one attempt, so a zero-value Config never retries by accident.
 	Attempts int
 
 	// InitialDelay is the duration to wait before the second attempt.
@@ -21,12 +28,405 @@ type Config struct {
 	MaxDelay time.Duration
 
 	// Factor is the multiplier used to increase the delay after each attempt.
-	// For example, a factor of 2 will double the delay each time.
+	// For example, a factor of 2 will double the delay each time. A
+	// non-positive Factor is treated as 1 (the delay stays constant across
+	// attempts) rather than collapsing it to zero.
 	Factor float64
 
 	// Jitter, if true, adds randomness to the delay (up to the current delay duration)
-	// to help prevent thundering herd issues.
+	// to help prevent thundering herd issues. Ignored if JitterStrategy is
+	// set. For back-compat, Jitter without JitterStrategy behaves like
+	// FullJitter applied to the InitialDelay/Factor/MaxDelay-derived delay.
 	Jitter bool
+
+	// JitterStrategy, if set, is consulted for the delay before each retry
+	// instead of the InitialDelay/Factor/Jitter computation. Do still caps
+	// the resulting delay at MaxDelay; Backoff.Wait does not. It
+	// lets callers plug in jitter strategies (FullJitter, EqualJitter,
+	// DecorrelatedJitter) that need to keep state across attempts. Named
+	// distinctly from the package-level Backoff type to avoid confusion
+	// between the two.
+	JitterStrategy BackoffStrategy
+
+	// IsRetryable, if set, is consulted after each failed op() call. If it
+	// returns false, Do returns the error immediately without sleeping or
+	// consuming further attempts. If nil, all errors are treated as
+	// retryable (subject to the other Config fields).
+	IsRetryable func(error) bool
+
+	// MaxElapsedTime bounds the total time since Do started, including time
+	// spent in op as well as waiting between attempts. If the next delay
+	// (for example a server-suggested throttle, see Throttled) would push
+	// the next attempt past this budget, Do gives up and returns the error
+	// immediately instead of sleeping. If zero, no limit is applied.
+	MaxElapsedTime time.Duration
+
+	// OnRetry, if set, is called before each retry, with the number of the
+	// attempt that just failed (starting at 1), the error it returned, and
+	// the delay before the next attempt. It is not called when Do gives up
+	// instead of retrying, including after the final attempt.
+	OnRetry func(attempt int, err error, nextDelay time.Duration)
+
+	// OnGiveUp, if set, is called once Do stops retrying and is about to
+	// return a non-nil error, with the total number of attempts made.
+	OnGiveUp func(attempts int, err error)
+
+	// Metrics, if set, receives counter increments for attempt outcomes and
+	// a delay observation before each retry, so callers can wire up
+	// Prometheus (or any other backend) without wrapping op.
+	Metrics Metrics
+
+	// Logger, if set, receives structured logging for each attempt and for
+	// giving up. If nil, Do logs nothing.
+	Logger *slog.Logger
+}
+
+// Metrics lets callers observe Do's retry behavior without wrapping op.
+type Metrics interface {
+	// IncAttempt is called with "success" when an attempt succeeds, "retry"
+	// before each retry, and "give_up" when Do returns an error. If ctx is
+	// cancelled while Do waits to retry, that attempt is counted as both
+	// "retry" and "give_up".
+	IncAttempt(outcome string)
+
+	// ObserveDelay is called with the delay computed before each retry.
+	ObserveDelay(d time.Duration)
+}
+
+// permanentError wraps an error to signal that Do should stop retrying
+// immediately, regardless of IsRetryable or the number of attempts remaining.
+type permanentError struct {
+	err error
+}
+
+func (p *permanentError) Error() string { return p.err.Error() }
+
+// Unwrap allows errors.Is and errors.As to see through to the underlying
+// cause of a permanent error.
+func (p *permanentError) Unwrap() error { return p.err }
+
+// Permanent wraps err so that Do treats it as non-retryable and returns it
+// immediately. If err is nil, Permanent returns nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// Throttled is implemented by errors that carry a server-suggested backoff
+// duration, such as an HTTP response's Retry-After header. When an error
+// returned from op() implements Throttled, Do uses its RetryAfter duration
+// for the next attempt's delay whenever it is longer than the computed
+// exponential backoff, still capped at Config.MaxDelay.
+type Throttled interface {
+	RetryAfter() time.Duration
+}
+
+// throttledError wraps an error with a server-suggested backoff duration.
+type throttledError struct {
+	err        error
+	retryAfter time.Duration
+}
+
+func (t *throttledError) Error() string { return t.err.Error() }
+
+// Unwrap allows errors.Is and errors.As to see through to the underlying
+// cause of a throttled error.
+func (t *throttledError) Unwrap() error { return t.err }
+
+// RetryAfter implements Throttled.
+func (t *throttledError) RetryAfter() time.Duration { return t.retryAfter }
+
+// WithRetryAfter wraps err so that Do treats d as a server-suggested backoff
+// for the next attempt, overriding the computed delay if d is larger. If err
+// is nil, WithRetryAfter returns nil.
+func WithRetryAfter(err error, d time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &throttledError{err: err, retryAfter: d}
+}
+
+// RetryAfterFromResponse inspects resp's Retry-After header and, if present
+// and parseable, wraps err with that delay via WithRetryAfter. It supports
+// both delta-seconds ("120") and HTTP-date formats. If resp is nil, err is
+// nil, or the header is absent or unparseable, err is returned unchanged.
+func RetryAfterFromResponse(resp *http.Response, err error) error {
+	if resp == nil || err == nil {
+		return err
+	}
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return err
+	}
+	if secs, parseErr := strconv.Atoi(v); parseErr == nil {
+		return WithRetryAfter(err, time.Duration(secs)*time.Second)
+	}
+	if when, parseErr := http.ParseTime(v); parseErr == nil {
+		if d := time.Until(when); d > 0 {
+			return WithRetryAfter(err, d)
+		}
+	}
+	return err
+}
+
+// BackoffStrategy computes the delay to use before each successive retry
+// attempt. Implementations own whatever state they need (an attempt
+// counter, a previous delay) rather than relying on Do to track it, so a
+// strategy value must not be shared across concurrent Do calls.
+type BackoffStrategy interface {
+	// NextDelay returns the duration to sleep before the next attempt.
+	NextDelay() time.Duration
+
+	// Reset clears any accumulated state, as if the strategy were freshly
+	// constructed.
+	Reset()
+}
+
+// FullJitter implements the "full jitter" strategy: sleep = rand(0,
+// min(Cap, Base*2^attempt)). It is the AWS-recommended default for
+// spreading out retries under contention.
+type FullJitter struct {
+	Base time.Duration
+	Cap  time.Duration
+
+	next time.Duration
+}
+
+// NextDelay implements BackoffStrategy.
+func (f *FullJitter) NextDelay() time.Duration {
+	temp := f.advance()
+	if temp <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(temp)))
+}
+
+// Reset implements BackoffStrategy.
+func (f *FullJitter) Reset() {
+	f.next = 0
+}
+
+func (f *FullJitter) advance() time.Duration {
+	if f.next <= 0 {
+		f.next = f.Base
+	}
+	temp := f.next
+	if f.Cap > 0 && temp > f.Cap {
+		temp = f.Cap
+	}
+	f.next *= 2
+	return temp
+}
+
+// EqualJitter implements the "equal jitter" strategy: sleep = temp/2 +
+// rand(0, temp/2), where temp = min(Cap, Base*2^attempt). It spreads
+// retries like FullJitter while guaranteeing at least half of the
+// un-jittered delay is always waited out.
+type EqualJitter struct {
+	Base time.Duration
+	Cap  time.Duration
+
+	next time.Duration
+}
+
+// NextDelay implements BackoffStrategy.
+func (e *EqualJitter) NextDelay() time.Duration {
+	temp := e.advance()
+	half := temp / 2
+	if half <= 0 {
+		return half
+	}
+	return half + time.Duration(rand.Int63n(int64(half)))
+}
+
+// Reset implements BackoffStrategy.
+func (e *EqualJitter) Reset() {
+	e.next = 0
+}
+
+func (e *EqualJitter) advance() time.Duration {
+	if e.next <= 0 {
+		e.next = e.Base
+	}
+	temp := e.next
+	if e.Cap > 0 && temp > e.Cap {
+		temp = e.Cap
+	}
+	e.next *= 2
+	return temp
+}
+
+// DecorrelatedJitter implements the "decorrelated jitter" strategy: sleep =
+// min(Cap, rand(Base, prev*3)), seeded with prev = Base. Unlike FullJitter
+// and EqualJitter, each delay is derived from the previous one rather than
+// from the attempt count. Draws above Cap are clamped to Cap, so once the
+// delays have grown near Cap many of them equal Cap exactly.
+type DecorrelatedJitter struct {
+	Base time.Duration
+	Cap  time.Duration
+
+	prev time.Duration
+}
+
+// Reset implements BackoffStrategy.
+func (d *DecorrelatedJitter) Reset() {
+	d.prev = 0
+}
+
+// NextDelay implements BackoffStrategy.
+func (d *DecorrelatedJitter) NextDelay() time.Duration {
+	if d.prev <= 0 {
+		d.prev = d.Base
+	}
+
+	span := d.prev*3 - d.Base
+	next := d.Base
+	if span > 0 {
+		next += time.Duration(rand.Int63n(int64(span)))
+	}
+	if d.Cap > 0 && next > d.Cap {
+		next = d.Cap
+	}
+
+	d.prev = next
+	return next
+}
+
+// RetryableHTTPStatus reports whether an HTTP response with the given status
+// code should be treated as a transient failure worth retrying. Callers can
+// plug this into Config.IsRetryable for HTTP-based operations.
+func RetryableHTTPStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// Backoff is a stateful retry-delay iterator for callers whose control flow
+// doesn't fit the single op() function shape of Do, e.g. streaming or
+// producer loops that want to reset the delay after partial progress. Do is
+// implemented on top of Backoff so both APIs draw delays from the same
+// computation; Do additionally honors Throttled errors and caps every delay
+// at Config.MaxDelay, which Backoff.Wait does not.
+//
+// A Backoff is not safe for concurrent use.
+type Backoff struct {
+	ctx context.Context
+	cfg Config
+
+	numRetries   int
+	nextDelayMin time.Duration
+	nextDelayMax time.Duration
+}
+
+// New creates a Backoff governed by cfg. The Backoff stops early once ctx is
+// cancelled.
+func New(ctx context.Context, cfg Config) *Backoff {
+	b := &Backoff{ctx: ctx, cfg: cfg}
+	b.Reset()
+	return b
+}
+
+// Reset clears accumulated retry state (NumRetries, the current delay
+// bounds, and, if Config.JitterStrategy is set, the strategy's own state)
+// as if the Backoff were freshly constructed. Callers use this after
+// partial progress so the next failure isn't treated as a continuation of
+// the previous backoff run.
+func (b *Backoff) Reset() {
+	b.numRetries = 0
+	b.nextDelayMax = b.cfg.InitialDelay
+	if b.cfg.Jitter || b.cfg.JitterStrategy != nil {
+		b.nextDelayMin = 0
+	} else {
+		b.nextDelayMin = b.cfg.InitialDelay
+	}
+	if b.cfg.JitterStrategy != nil {
+		b.cfg.JitterStrategy.Reset()
+	}
+}
+
+// Ongoing reports whether the caller should make another attempt: the
+// context hasn't been cancelled and, if Config.Attempts is set, fewer than
+// Attempts total attempts (the initial try plus retries) have been made.
+// Config.Attempts <= 0 means retry indefinitely until ctx is done, which
+// differs from Do's treatment of the same field (see Config.Attempts).
+func (b *Backoff) Ongoing() bool {
+	if b.ctx.Err() != nil {
+		return false
+	}
+	return b.cfg.Attempts <= 0 || b.numRetries < b.cfg.Attempts
+}
+
+// Err returns the context's error if the Backoff stopped because the
+// context was cancelled, or nil otherwise.
+func (b *Backoff) Err() error {
+	return b.ctx.Err()
+}
+
+// NumRetries returns the number of times Wait has been called so far.
+func (b *Backoff) NumRetries() int {
+	return b.numRetries
+}
+
+// Wait sleeps for a duration randomized between the current nextDelayMin
+// and nextDelayMax bounds (or computed by Config.JitterStrategy, if set),
+// then multiplies both bounds by Config.Factor, capped by Config.MaxDelay.
+// It returns early,
+// without completing the sleep, if the context is done.
+func (b *Backoff) Wait() {
+	d := b.nextDelay()
+	b.numRetries++
+
+	select {
+	case <-time.After(d):
+	case <-b.ctx.Done():
+	}
+}
+
+// nextDelay computes the delay for the upcoming attempt and advances the
+// iterator's internal bounds for the one after that.
+func (b *Backoff) nextDelay() time.Duration {
+	if b.cfg.JitterStrategy != nil {
+		return b.cfg.JitterStrategy.NextDelay()
+	}
+
+	lo, hi := b.nextDelayMin, b.nextDelayMax
+	if b.cfg.MaxDelay > 0 {
+		if lo > b.cfg.MaxDelay {
+			lo = b.cfg.MaxDelay
+		}
+		if hi > b.cfg.MaxDelay {
+			hi = b.cfg.MaxDelay
+		}
+	}
+
+	d := lo
+	if hi > lo {
+		d += time.Duration(rand.Int63n(int64(hi - lo)))
+	}
+
+	// A non-positive Factor defaults to 1 (constant delay) rather than
+	// collapsing every subsequent delay to zero.
+	factor := b.cfg.Factor
+	if factor <= 0 {
+		factor = 1
+	}
+	b.nextDelayMin = time.Duration(float64(b.nextDelayMin) * factor)
+	b.nextDelayMax = time.Duration(float64(b.nextDelayMax) * factor)
+	if b.cfg.MaxDelay > 0 {
+		if b.nextDelayMin > b.cfg.MaxDelay {
+			b.nextDelayMin = b.cfg.MaxDelay
+		}
+		if b.nextDelayMax > b.cfg.MaxDelay {
+			b.nextDelayMax = b.cfg.MaxDelay
+		}
+	}
+
+	return d
 }
 
 // Do executes the provided operation function and retries it on error
@@ -35,33 +435,61 @@ type Config struct {
 //   - The maximum number of attempts is reached,
 //   - The provided context is cancelled.
 //
-// Do returns nil on success or the last error encountered.
+// Do returns nil on success, ctx.Err() if ctx is cancelled while waiting
+// to retry, or otherwise the last error encountered. It is
+// implemented on top of Backoff for delay computation, but, unlike
+// Backoff.Ongoing, treats a non-positive Config.Attempts as exactly one
+// attempt rather than unbounded retries (see Config.Attempts).
 func Do(ctx context.Context, op func() error, cfg Config) error {
 	// Ensure at least one attempt.
 	if cfg.Attempts < 1 {
 		cfg.Attempts = 1
 	}
 
-	// Use the initial delay for the first retry (if needed).
-	delay := cfg.InitialDelay
+	b := New(ctx, cfg)
+	start := time.Now()
 	var err error
 
 	for attempt := 1; attempt <= cfg.Attempts; attempt++ {
 		// Attempt the operation.
 		if err = op(); err == nil {
+			if cfg.Metrics != nil {
+				cfg.Metrics.IncAttempt("success")
+			}
 			return nil
 		}
 
+		// A permanent error short-circuits retrying entirely, even if it
+		// was wrapped (e.g. fmt.Errorf("...: %w", retry.Permanent(err))).
+		var perm *permanentError
+		if errors.As(err, &perm) {
+			giveUp(cfg, attempt, err)
+			return err
+		}
+
+		// Errors classified as non-retryable are returned immediately,
+		// without sleeping or consuming further attempts.
+		if cfg.IsRetryable != nil && !cfg.IsRetryable(err) {
+			giveUp(cfg, attempt, err)
+			return err
+		}
+
 		// If this was the final attempt, break out of the loop.
 		if attempt == cfg.Attempts {
 			break
 		}
 
 		// Determine how long to wait before the next attempt.
-		sleepDuration := delay
-		if cfg.Jitter {
-			// Apply jitter: randomize the delay between 0 and the calculated delay.
-			sleepDuration = time.Duration(rand.Int63n(int64(delay)))
+		sleepDuration := b.nextDelay()
+		b.numRetries++
+
+		// A server-suggested throttle overrides the computed delay if
+		// larger, even if it was wrapped (e.g. fmt.Errorf("...: %w", ...)).
+		var throttled Throttled
+		if errors.As(err, &throttled) {
+			if ra := throttled.RetryAfter(); ra > sleepDuration {
+				sleepDuration = ra
+			}
 		}
 
 		// Respect the maximum delay if it's set.
@@ -69,19 +497,48 @@ func Do(ctx context.Context, op func() error, cfg Config) error {
 			sleepDuration = cfg.MaxDelay
 		}
 
+		// Give up if honoring the delay would exceed the overall time budget.
+		if cfg.MaxElapsedTime > 0 && time.Since(start)+sleepDuration > cfg.MaxElapsedTime {
+			giveUp(cfg, attempt, err)
+			return err
+		}
+
+		if cfg.Metrics != nil {
+			cfg.Metrics.IncAttempt("retry")
+			cfg.Metrics.ObserveDelay(sleepDuration)
+		}
+		if cfg.OnRetry != nil {
+			cfg.OnRetry(attempt, err, sleepDuration)
+		}
+		if cfg.Logger != nil {
+			cfg.Logger.Debug("retry: attempt failed, retrying",
+				"attempt", attempt, "error", err, "delay", sleepDuration)
+		}
+
 		// Wait for either the delay period or context cancellation.
 		select {
 		case <-time.After(sleepDuration):
-			// Increase delay for the next attempt using the exponential backoff factor.
-			delay = time.Duration(float64(delay) * cfg.Factor)
-			if cfg.MaxDelay > 0 && delay > cfg.MaxDelay {
-				delay = cfg.MaxDelay
-			}
 		case <-ctx.Done():
+			giveUp(cfg, attempt, ctx.Err())
 			return ctx.Err()
 		}
 	}
 
 	// Return the last error if all attempts fail.
+	giveUp(cfg, cfg.Attempts, err)
 	return err
 }
+
+// giveUp reports that Do is about to return a non-nil error after attempts
+// tries, via cfg's Metrics, OnGiveUp, and Logger hooks.
+func giveUp(cfg Config, attempts int, err error) {
+	if cfg.Metrics != nil {
+		cfg.Metrics.IncAttempt("give_up")
+	}
+	if cfg.OnGiveUp != nil {
+		cfg.OnGiveUp(attempts, err)
+	}
+	if cfg.Logger != nil {
+		cfg.Logger.Error("retry: giving up", "attempts", attempts, "error", err)
+	}
+}
@@ -3,6 +3,8 @@ package retry
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/http"
 	"testing"
 	"time"
 )
@@ -164,6 +166,490 @@ func TestMaxDelay(t *testing.T) {
 	}
 }
 
+func TestFactorDefault(t *testing.T) {
+	// A zero Factor should hold the delay constant, not collapse it to 0
+	// after the first attempt.
+	b := New(context.Background(), Config{InitialDelay: 10 * time.Millisecond})
+
+	for i := 0; i < 3; i++ {
+		d := b.nextDelay()
+		if d != 10*time.Millisecond {
+			t.Errorf("nextDelay() = %v, want 10ms", d)
+		}
+	}
+}
+
+func TestPermanent(t *testing.T) {
+	cfg := Config{
+		Attempts:     5,
+		InitialDelay: 10 * time.Millisecond,
+		Factor:       2,
+	}
+
+	sentinel := errors.New("do not retry me")
+	count := 0
+	err := Do(context.Background(), func() error {
+		count++
+		return Permanent(sentinel)
+	}, cfg)
+
+	if !errors.Is(err, sentinel) {
+		t.Errorf("expected error to wrap sentinel, got: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("expected exactly 1 attempt, got: %d", count)
+	}
+}
+
+func TestPermanentWrapped(t *testing.T) {
+	cfg := Config{
+		Attempts:     5,
+		InitialDelay: 10 * time.Millisecond,
+		Factor:       2,
+	}
+
+	sentinel := errors.New("do not retry me")
+	count := 0
+	err := Do(context.Background(), func() error {
+		count++
+		return fmt.Errorf("call api: %w", Permanent(sentinel))
+	}, cfg)
+
+	if !errors.Is(err, sentinel) {
+		t.Errorf("expected error to wrap sentinel, got: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("expected exactly 1 attempt, got: %d", count)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	cfg := Config{
+		Attempts:     5,
+		InitialDelay: 10 * time.Millisecond,
+		Factor:       2,
+		IsRetryable: func(err error) bool {
+			return err.Error() != "fatal"
+		},
+	}
+
+	count := 0
+	err := Do(context.Background(), func() error {
+		count++
+		if count == 2 {
+			return errors.New("fatal")
+		}
+		return errors.New("temporary error")
+	}, cfg)
+
+	if err == nil || err.Error() != "fatal" {
+		t.Errorf("expected fatal error, got: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("expected exactly 2 attempts, got: %d", count)
+	}
+}
+
+func TestRetryableHTTPStatus(t *testing.T) {
+	tests := []struct {
+		code      int
+		retryable bool
+	}{
+		{http.StatusTooManyRequests, true},
+		{http.StatusServiceUnavailable, true},
+		{http.StatusGatewayTimeout, true},
+		{http.StatusOK, false},
+		{http.StatusBadRequest, false},
+		{http.StatusInternalServerError, false},
+	}
+
+	for _, tt := range tests {
+		if got := RetryableHTTPStatus(tt.code); got != tt.retryable {
+			t.Errorf("RetryableHTTPStatus(%d) = %v, want %v", tt.code, got, tt.retryable)
+		}
+	}
+}
+
+func TestWithRetryAfter(t *testing.T) {
+	cfg := Config{
+		Attempts:     3,
+		InitialDelay: 5 * time.Millisecond,
+		Factor:       2,
+	}
+
+	var lastTime time.Time
+	var observedDelay time.Duration
+	count := 0
+
+	err := Do(context.Background(), func() error {
+		now := time.Now()
+		if !lastTime.IsZero() {
+			observedDelay = now.Sub(lastTime)
+		}
+		lastTime = now
+		count++
+		return WithRetryAfter(errors.New("throttled"), 50*time.Millisecond)
+	}, cfg)
+
+	if err == nil {
+		t.Error("expected error but got nil")
+	}
+	if observedDelay < 50*time.Millisecond {
+		t.Errorf("expected delay to honor RetryAfter, got: %v", observedDelay)
+	}
+}
+
+func TestWithRetryAfterWrapped(t *testing.T) {
+	cfg := Config{
+		Attempts:     3,
+		InitialDelay: 5 * time.Millisecond,
+		Factor:       2,
+	}
+
+	var lastTime time.Time
+	var observedDelay time.Duration
+
+	err := Do(context.Background(), func() error {
+		now := time.Now()
+		if !lastTime.IsZero() {
+			observedDelay = now.Sub(lastTime)
+		}
+		lastTime = now
+		return fmt.Errorf("call api: %w", WithRetryAfter(errors.New("throttled"), 50*time.Millisecond))
+	}, cfg)
+
+	if err == nil {
+		t.Error("expected error but got nil")
+	}
+	if observedDelay < 50*time.Millisecond {
+		t.Errorf("expected delay to honor wrapped RetryAfter, got: %v", observedDelay)
+	}
+}
+
+func TestMaxElapsedTime(t *testing.T) {
+	cfg := Config{
+		Attempts:       5,
+		InitialDelay:   5 * time.Millisecond,
+		Factor:         2,
+		MaxElapsedTime: 20 * time.Millisecond,
+	}
+
+	count := 0
+	err := Do(context.Background(), func() error {
+		count++
+		return WithRetryAfter(errors.New("throttled"), time.Second)
+	}, cfg)
+
+	if err == nil {
+		t.Error("expected error but got nil")
+	}
+	if count != 1 {
+		t.Errorf("expected to give up after the first throttle exceeded the budget, got %d attempts", count)
+	}
+}
+
+func TestRetryAfterFromResponse(t *testing.T) {
+	baseErr := errors.New("rate limited")
+
+	t.Run("delta_seconds", func(t *testing.T) {
+		resp := &http.Response{Header: http.Header{"Retry-After": []string{"2"}}}
+		wrapped := RetryAfterFromResponse(resp, baseErr)
+
+		th, ok := wrapped.(Throttled)
+		if !ok {
+			t.Fatal("expected wrapped error to implement Throttled")
+		}
+		if th.RetryAfter() != 2*time.Second {
+			t.Errorf("expected 2s, got: %v", th.RetryAfter())
+		}
+	})
+
+	t.Run("http_date", func(t *testing.T) {
+		when := time.Now().Add(3 * time.Second).UTC()
+		resp := &http.Response{Header: http.Header{"Retry-After": []string{when.Format(http.TimeFormat)}}}
+		wrapped := RetryAfterFromResponse(resp, baseErr)
+
+		th, ok := wrapped.(Throttled)
+		if !ok {
+			t.Fatal("expected wrapped error to implement Throttled")
+		}
+		if th.RetryAfter() <= 0 {
+			t.Errorf("expected a positive delay, got: %v", th.RetryAfter())
+		}
+	})
+
+	t.Run("missing_header", func(t *testing.T) {
+		resp := &http.Response{Header: http.Header{}}
+		wrapped := RetryAfterFromResponse(resp, baseErr)
+
+		if !errors.Is(wrapped, baseErr) {
+			t.Errorf("expected original error, got: %v", wrapped)
+		}
+	})
+}
+
+func TestFullJitter(t *testing.T) {
+	fj := &FullJitter{Base: 10 * time.Millisecond, Cap: 40 * time.Millisecond}
+
+	for i := 0; i < 5; i++ {
+		d := fj.NextDelay()
+		if d < 0 || d > 40*time.Millisecond {
+			t.Errorf("NextDelay() = %v, want within [0, 40ms]", d)
+		}
+	}
+}
+
+func TestEqualJitter(t *testing.T) {
+	ej := &EqualJitter{Base: 10 * time.Millisecond, Cap: 40 * time.Millisecond}
+
+	for i := 0; i < 5; i++ {
+		d := ej.NextDelay()
+		if d < 0 || d > 40*time.Millisecond {
+			t.Errorf("NextDelay() = %v, want within [0, 40ms]", d)
+		}
+	}
+}
+
+func TestDecorrelatedJitter(t *testing.T) {
+	dj := &DecorrelatedJitter{Base: 10 * time.Millisecond, Cap: 100 * time.Millisecond}
+
+	for i := 0; i < 10; i++ {
+		d := dj.NextDelay()
+		if d < 10*time.Millisecond || d > 100*time.Millisecond {
+			t.Errorf("NextDelay() = %v, want within [10ms, 100ms]", d)
+		}
+	}
+}
+
+func TestDoWithBackoffStrategy(t *testing.T) {
+	cfg := Config{
+		Attempts:       3,
+		JitterStrategy: &DecorrelatedJitter{Base: 5 * time.Millisecond, Cap: 20 * time.Millisecond},
+	}
+
+	count := 0
+	err := Do(context.Background(), func() error {
+		count++
+		return errors.New("temporary error")
+	}, cfg)
+
+	if err == nil {
+		t.Error("expected error but got nil")
+	}
+	if count != 3 {
+		t.Errorf("expected 3 attempts, got: %d", count)
+	}
+}
+
+func TestBackoffResetPropagatesToStrategy(t *testing.T) {
+	strategy := &FullJitter{Base: 10 * time.Millisecond, Cap: 100 * time.Millisecond}
+	cfg := Config{
+		Attempts:       5,
+		JitterStrategy: strategy,
+	}
+
+	b := New(context.Background(), cfg)
+	for i := 0; i < 3; i++ {
+		b.Wait()
+	}
+
+	if strategy.next == 0 {
+		t.Fatal("expected strategy to have advanced before Reset")
+	}
+
+	b.Reset()
+
+	if strategy.next != 0 {
+		t.Errorf("expected Reset to clear strategy state, got next=%v", strategy.next)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	cfg := Config{
+		Attempts:     3,
+		InitialDelay: 5 * time.Millisecond,
+		Factor:       2,
+	}
+
+	b := New(context.Background(), cfg)
+
+	attempts := 0
+	var opErr error
+	for b.Ongoing() {
+		attempts++
+		opErr = errors.New("temporary error")
+		if attempts == 3 {
+			opErr = nil
+		}
+		if opErr == nil {
+			break
+		}
+		b.Wait()
+	}
+
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got: %d", attempts)
+	}
+	if b.NumRetries() != 2 {
+		t.Errorf("expected 2 retries, got: %d", b.NumRetries())
+	}
+	if b.Err() != nil {
+		t.Errorf("expected no error, got: %v", b.Err())
+	}
+}
+
+func TestBackoffExhausted(t *testing.T) {
+	cfg := Config{
+		Attempts:     3,
+		InitialDelay: 5 * time.Millisecond,
+		Factor:       2,
+	}
+
+	b := New(context.Background(), cfg)
+
+	attempts := 0
+	for b.Ongoing() {
+		attempts++
+		b.Wait()
+	}
+
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got: %d", attempts)
+	}
+	if b.NumRetries() != 3 {
+		t.Errorf("expected 3 retries, got: %d", b.NumRetries())
+	}
+}
+
+func TestBackoffContext(t *testing.T) {
+	cfg := Config{
+		InitialDelay: 100 * time.Millisecond,
+		Factor:       2,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	b := New(ctx, cfg)
+
+	attempts := 0
+	for b.Ongoing() {
+		attempts++
+		b.Wait()
+	}
+
+	if attempts > 2 {
+		t.Errorf("expected at most 2 attempts before context timeout, got: %d", attempts)
+	}
+	if !errors.Is(b.Err(), context.DeadlineExceeded) {
+		t.Errorf("expected DeadlineExceeded, got: %v", b.Err())
+	}
+}
+
+func TestBackoffReset(t *testing.T) {
+	cfg := Config{
+		Attempts:     2,
+		InitialDelay: 5 * time.Millisecond,
+		Factor:       2,
+	}
+
+	b := New(context.Background(), cfg)
+	b.Wait()
+	if b.NumRetries() != 1 {
+		t.Fatalf("expected 1 retry before reset, got: %d", b.NumRetries())
+	}
+
+	b.Reset()
+	if b.NumRetries() != 0 {
+		t.Errorf("expected NumRetries to be 0 after Reset, got: %d", b.NumRetries())
+	}
+	if !b.Ongoing() {
+		t.Error("expected Ongoing() to be true after Reset")
+	}
+}
+
+type fakeMetrics struct {
+	outcomes []string
+	delays   []time.Duration
+}
+
+func (m *fakeMetrics) IncAttempt(outcome string)    { m.outcomes = append(m.outcomes, outcome) }
+func (m *fakeMetrics) ObserveDelay(d time.Duration) { m.delays = append(m.delays, d) }
+
+func TestOnRetryAndOnGiveUp(t *testing.T) {
+	cfg := Config{
+		Attempts:     3,
+		InitialDelay: 5 * time.Millisecond,
+		Factor:       2,
+	}
+
+	var retryAttempts []int
+	cfg.OnRetry = func(attempt int, err error, nextDelay time.Duration) {
+		retryAttempts = append(retryAttempts, attempt)
+	}
+
+	var giveUpAttempts int
+	var giveUpErr error
+	cfg.OnGiveUp = func(attempts int, err error) {
+		giveUpAttempts = attempts
+		giveUpErr = err
+	}
+
+	err := Do(context.Background(), func() error {
+		return errors.New("temporary error")
+	}, cfg)
+
+	if err == nil {
+		t.Fatal("expected error but got nil")
+	}
+	if len(retryAttempts) != 2 {
+		t.Errorf("expected OnRetry called twice, got: %v", retryAttempts)
+	}
+	if giveUpAttempts != 3 {
+		t.Errorf("expected OnGiveUp called with 3 attempts, got: %d", giveUpAttempts)
+	}
+	if giveUpErr != err {
+		t.Errorf("expected OnGiveUp to receive the final error, got: %v", giveUpErr)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	cfg := Config{
+		Attempts:     3,
+		InitialDelay: 5 * time.Millisecond,
+		Factor:       2,
+	}
+
+	m := &fakeMetrics{}
+	cfg.Metrics = m
+
+	count := 0
+	err := Do(context.Background(), func() error {
+		count++
+		if count == 2 {
+			return nil
+		}
+		return errors.New("temporary error")
+	}, cfg)
+
+	if err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+
+	want := []string{"retry", "success"}
+	if len(m.outcomes) != len(want) {
+		t.Fatalf("expected outcomes %v, got: %v", want, m.outcomes)
+	}
+	for i, o := range want {
+		if m.outcomes[i] != o {
+			t.Errorf("outcome[%d] = %q, want %q", i, m.outcomes[i], o)
+		}
+	}
+	if len(m.delays) != 1 {
+		t.Errorf("expected 1 delay observation, got: %d", len(m.delays))
+	}
+}
+
 func TestZeroAttempts(t *testing.T) {
 	cfg := Config{
 		Attempts:     0, // Should be corrected to 1